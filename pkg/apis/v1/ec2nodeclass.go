@@ -0,0 +1,147 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EC2NodeClassSpec is the top level specification for the AWS Karpenter provider.
+// This will contain configuration necessary to launch instances in AWS.
+type EC2NodeClassSpec struct {
+	// AMISelectorTerms is a list of or ami selector terms. The terms are ORed.
+	// +kubebuilder:validation:MaxItems:=30
+	// +optional
+	AMISelectorTerms []AMISelectorTerm `json:"amiSelectorTerms,omitempty"`
+	// SubnetSelectorTerms is a list of or subnet selector terms. The terms are ORed.
+	// +kubebuilder:validation:MaxItems:=30
+	// +optional
+	SubnetSelectorTerms []SubnetSelectorTerm `json:"subnetSelectorTerms,omitempty"`
+	// SecurityGroupSelectorTerms is a list of or security group selector terms. The terms are ORed.
+	// +kubebuilder:validation:MaxItems:=30
+	// +optional
+	SecurityGroupSelectorTerms []SecurityGroupSelectorTerm `json:"securityGroupSelectorTerms,omitempty"`
+	// Role is the AWS identity that nodes use. This field is immutable.
+	// +optional
+	Role string `json:"role,omitempty"`
+	// InstanceProfile is the AWS entity that instances use.
+	// This field is mutually exclusive from role.
+	// +optional
+	InstanceProfile *string `json:"instanceProfile,omitempty"`
+	// Tags to be applied on ec2 resources like instances and launch templates.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+	// BlockDeviceMappings to be applied to provisioned nodes.
+	// +kubebuilder:validation:MaxItems:=50
+	// +optional
+	BlockDeviceMappings []*BlockDeviceMapping `json:"blockDeviceMappings,omitempty"`
+}
+
+// AMISelectorTerm defines selection logic for an ami used by Karpenter to launch nodes.
+type AMISelectorTerm struct {
+	// Alias specifies which EKS optimized AMI to select.
+	// +optional
+	Alias string `json:"alias,omitempty"`
+	// Tags is a map of key/value tags used to select amis.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+	// ID is the ami id in EC2.
+	// +optional
+	ID string `json:"id,omitempty"`
+}
+
+// SubnetSelectorTerm defines selection logic for a subnet used by Karpenter to launch nodes.
+type SubnetSelectorTerm struct {
+	// Tags is a map of key/value tags used to select subnets.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+	// ID is the subnet id in EC2.
+	// +optional
+	ID string `json:"id,omitempty"`
+}
+
+// SecurityGroupSelectorTerm defines selection logic for a security group used by Karpenter to launch nodes.
+type SecurityGroupSelectorTerm struct {
+	// Tags is a map of key/value tags used to select security groups.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+	// ID is the security group id in EC2.
+	// +optional
+	ID string `json:"id,omitempty"`
+	// Name is the security group name in EC2.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// BlockDeviceMapping defines the device mapping for a volume attached to a provisioned node.
+type BlockDeviceMapping struct {
+	// The device name (for example, /dev/sdh or xvdh).
+	// +optional
+	DeviceName *string `json:"deviceName,omitempty"`
+	// EBS contains parameters used to automatically set up EBS volumes when an instance is launched.
+	// +optional
+	EBS *BlockDevice `json:"ebs,omitempty"`
+	// RootVolume is a flag indicating if this device is mounted as kubelet and containerd root.
+	// +optional
+	RootVolume bool `json:"rootVolume,omitempty"`
+}
+
+// BlockDevice contains parameters used to automatically set up EBS volumes when an instance is launched.
+// +kubebuilder:validation:XValidation:message="throughput may only be set on a gp3 volume",rule="!has(self.throughput) || self.volumeType == 'gp3'"
+// +kubebuilder:validation:XValidation:message="iops must be in the range 3000-16000 for a gp3 volume",rule="self.volumeType != 'gp3' || !has(self.iops) || (self.iops >= 3000 && self.iops <= 16000)"
+type BlockDevice struct {
+	// DeleteOnTermination indicates whether the EBS volume is deleted on instance termination.
+	// +optional
+	DeleteOnTermination *bool `json:"deleteOnTermination,omitempty"`
+	// Encrypted indicates whether the EBS volume is encrypted.
+	// +optional
+	Encrypted *bool `json:"encrypted,omitempty"`
+	// IOPS is the number of I/O operations per second to provision for the volume.
+	// Required for io1 and io2 volumes, and valid for gp3. Omitting this value for
+	// gp3 volumes results in a default IOPS of 3,000.
+	// +kubebuilder:validation:Minimum:=100
+	// +kubebuilder:validation:Maximum:=64000
+	// +optional
+	IOPS *int64 `json:"iops,omitempty"`
+	// KMSKeyID is the AWS KMS key used to encrypt the volume.
+	// +optional
+	KMSKeyID *string `json:"kmsKeyID,omitempty"`
+	// SnapshotID is the ID of an EBS snapshot to use when creating the volume.
+	// +optional
+	SnapshotID *string `json:"snapshotID,omitempty"`
+	// Throughput is the throughput to provision for a gp3 volume, in MiB/s.
+	// Valid only for gp3 volumes; omitting this value results in a default
+	// throughput of 125.
+	// +kubebuilder:validation:Minimum:=125
+	// +kubebuilder:validation:Maximum:=1000
+	// +optional
+	Throughput *int64 `json:"throughput,omitempty"`
+	// VolumeSize is the size of the EBS volume.
+	// +optional
+	VolumeSize *resource.Quantity `json:"volumeSize,omitempty"`
+	// VolumeType is the volume type of the EBS volume.
+	// +kubebuilder:validation:Enum:={standard,io1,io2,gp2,sc1,st1,gp3}
+	// +optional
+	VolumeType *string `json:"volumeType,omitempty"`
+}
+
+// EC2NodeClass is the Schema for the EC2NodeClass API.
+type EC2NodeClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EC2NodeClassSpec `json:"spec,omitempty"`
+}
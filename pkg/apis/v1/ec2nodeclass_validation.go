@@ -0,0 +1,77 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	gp3MinIOPS       = 3000
+	gp3MaxIOPS       = 16000
+	minIOPS          = 100
+	maxIOPS          = 64000
+	gp3MinThroughput = 125
+	gp3MaxThroughput = 1000
+
+	volumeTypeGP3 = "gp3"
+)
+
+// Validate implements apis.Validatable. In addition to the CEL rules declared on the
+// CRD (which protect direct API server writes), this runs the same checks in-process
+// for callers that build EC2NodeClasses without going through the API server.
+func (in *EC2NodeClass) Validate(ctx context.Context) (errs *apis.FieldError) {
+	return in.Spec.validate(ctx)
+}
+
+func (in *EC2NodeClassSpec) validate(ctx context.Context) (errs *apis.FieldError) {
+	for i, mapping := range in.BlockDeviceMappings {
+		errs = errs.Also(mapping.validate().ViaField("blockDeviceMappings").ViaIndex(i))
+	}
+	return errs
+}
+
+func (in *BlockDeviceMapping) validate() (errs *apis.FieldError) {
+	if in.EBS == nil {
+		return nil
+	}
+	return in.EBS.validate().ViaField("ebs")
+}
+
+func (in *BlockDevice) validate() (errs *apis.FieldError) {
+	volumeType := ""
+	if in.VolumeType != nil {
+		volumeType = *in.VolumeType
+	}
+	if in.IOPS != nil {
+		minimum, maximum := minIOPS, maxIOPS
+		if volumeType == volumeTypeGP3 {
+			minimum, maximum = gp3MinIOPS, gp3MaxIOPS
+		}
+		if int(*in.IOPS) < minimum || int(*in.IOPS) > maximum {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(*in.IOPS, minimum, maximum, "iops"))
+		}
+	}
+	if in.Throughput != nil {
+		if volumeType != volumeTypeGP3 {
+			errs = errs.Also(apis.ErrGeneric("throughput is only configurable for gp3 volumes", "throughput"))
+		} else if int(*in.Throughput) < gp3MinThroughput || int(*in.Throughput) > gp3MaxThroughput {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(*in.Throughput, gp3MinThroughput, gp3MaxThroughput, "throughput"))
+		}
+	}
+	return errs
+}
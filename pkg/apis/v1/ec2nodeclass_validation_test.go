@@ -0,0 +1,60 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samber/lo"
+
+	v1 "github.com/aws/karpenter-provider-aws/pkg/apis/v1"
+)
+
+func TestBlockDeviceValidation(t *testing.T) {
+	cases := []struct {
+		name    string
+		device  v1.BlockDevice
+		wantErr bool
+	}{
+		{"gp3 iops at lower bound is valid", v1.BlockDevice{VolumeType: lo.ToPtr("gp3"), IOPS: lo.ToPtr(int64(3000))}, false},
+		{"gp3 iops at upper bound is valid", v1.BlockDevice{VolumeType: lo.ToPtr("gp3"), IOPS: lo.ToPtr(int64(16000))}, false},
+		{"gp3 iops just below band is invalid", v1.BlockDevice{VolumeType: lo.ToPtr("gp3"), IOPS: lo.ToPtr(int64(2999))}, true},
+		{"gp3 iops just above band is invalid", v1.BlockDevice{VolumeType: lo.ToPtr("gp3"), IOPS: lo.ToPtr(int64(16001))}, true},
+		{"io2 iops at general lower bound is valid", v1.BlockDevice{VolumeType: lo.ToPtr("io2"), IOPS: lo.ToPtr(int64(100))}, false},
+		{"io2 iops at general upper bound is valid", v1.BlockDevice{VolumeType: lo.ToPtr("io2"), IOPS: lo.ToPtr(int64(64000))}, false},
+		{"io2 iops just below general band is invalid", v1.BlockDevice{VolumeType: lo.ToPtr("io2"), IOPS: lo.ToPtr(int64(99))}, true},
+		{"io2 iops just above general band is invalid", v1.BlockDevice{VolumeType: lo.ToPtr("io2"), IOPS: lo.ToPtr(int64(64001))}, true},
+		{"gp3 throughput at lower bound is valid", v1.BlockDevice{VolumeType: lo.ToPtr("gp3"), Throughput: lo.ToPtr(int64(125))}, false},
+		{"gp3 throughput at upper bound is valid", v1.BlockDevice{VolumeType: lo.ToPtr("gp3"), Throughput: lo.ToPtr(int64(1000))}, false},
+		{"gp3 throughput just below band is invalid", v1.BlockDevice{VolumeType: lo.ToPtr("gp3"), Throughput: lo.ToPtr(int64(124))}, true},
+		{"gp3 throughput just above band is invalid", v1.BlockDevice{VolumeType: lo.ToPtr("gp3"), Throughput: lo.ToPtr(int64(1001))}, true},
+		{"throughput on a non-gp3 volume is invalid", v1.BlockDevice{VolumeType: lo.ToPtr("gp2"), Throughput: lo.ToPtr(int64(125))}, true},
+		{"no iops or throughput set is valid", v1.BlockDevice{VolumeType: lo.ToPtr("gp2")}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodeClass := &v1.EC2NodeClass{
+				Spec: v1.EC2NodeClassSpec{
+					BlockDeviceMappings: []*v1.BlockDeviceMapping{{EBS: &tc.device}},
+				},
+			}
+			errs := nodeClass.Validate(context.Background())
+			if gotErr := errs != nil; gotErr != tc.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", errs, tc.wantErr)
+			}
+		})
+	}
+}
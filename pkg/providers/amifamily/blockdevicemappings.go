@@ -0,0 +1,68 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package amifamily resolves AMI- and volume-related EC2NodeClass fields into the
+// shapes the EC2 API expects when building RunInstances input.
+package amifamily
+
+import (
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/samber/lo"
+
+	v1 "github.com/aws/karpenter-provider-aws/pkg/apis/v1"
+)
+
+// BlockDeviceMappings translates an EC2NodeClass's BlockDeviceMappings into the shape
+// the EC2 API expects on RunInstances input, so RunInstancesInput actually
+// provisions volumes with the IOPS and (for gp3) throughput the user asked for instead
+// of silently falling back to AWS's per-volume-type defaults.
+func BlockDeviceMappings(nodeClass *v1.EC2NodeClass) []ec2types.BlockDeviceMapping {
+	return lo.Map(nodeClass.Spec.BlockDeviceMappings, func(bdm *v1.BlockDeviceMapping, _ int) ec2types.BlockDeviceMapping {
+		mapping := ec2types.BlockDeviceMapping{
+			DeviceName: bdm.DeviceName,
+		}
+		if bdm.EBS != nil {
+			mapping.Ebs = ebsBlockDevice(bdm.EBS)
+		}
+		return mapping
+	})
+}
+
+func ebsBlockDevice(ebs *v1.BlockDevice) *ec2types.EbsBlockDevice {
+	device := &ec2types.EbsBlockDevice{
+		DeleteOnTermination: ebs.DeleteOnTermination,
+		Encrypted:           ebs.Encrypted,
+		Iops:                toInt32(ebs.IOPS),
+		KmsKeyId:            ebs.KMSKeyID,
+		SnapshotId:          ebs.SnapshotID,
+		// Throughput is only meaningful for gp3 (enforced by EC2NodeClass validation), but we
+		// pass it through unconditionally here - the EC2 API itself rejects it for other
+		// volume types, so there's no need to duplicate that check at the launch template layer.
+		Throughput: toInt32(ebs.Throughput),
+	}
+	if ebs.VolumeType != nil {
+		device.VolumeType = ec2types.VolumeType(*ebs.VolumeType)
+	}
+	if ebs.VolumeSize != nil {
+		device.VolumeSize = lo.ToPtr(int32(ebs.VolumeSize.Value() / (1 << 30)))
+	}
+	return device
+}
+
+func toInt32(v *int64) *int32 {
+	if v == nil {
+		return nil
+	}
+	return lo.ToPtr(int32(*v))
+}
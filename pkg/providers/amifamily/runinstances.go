@@ -0,0 +1,37 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package amifamily
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/samber/lo"
+
+	v1 "github.com/aws/karpenter-provider-aws/pkg/apis/v1"
+)
+
+// RunInstancesInput builds the EC2 RunInstances input for launching a single instance
+// of instanceType from amiID, with the EBS volumes BlockDeviceMappings resolves from
+// nodeClass - this is the call site that actually gets the gp3 throughput/IOPS the
+// user requested onto the provisioned instance.
+func RunInstancesInput(nodeClass *v1.EC2NodeClass, amiID, instanceType string) *ec2.RunInstancesInput {
+	return &ec2.RunInstancesInput{
+		ImageId:             lo.ToPtr(amiID),
+		InstanceType:        ec2types.InstanceType(instanceType),
+		MinCount:            lo.ToPtr(int32(1)),
+		MaxCount:            lo.ToPtr(int32(1)),
+		BlockDeviceMappings: BlockDeviceMappings(nodeClass),
+	}
+}
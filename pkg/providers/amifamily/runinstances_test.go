@@ -0,0 +1,58 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package amifamily_test
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+
+	v1 "github.com/aws/karpenter-provider-aws/pkg/apis/v1"
+	"github.com/aws/karpenter-provider-aws/pkg/providers/amifamily"
+)
+
+func TestRunInstancesInput_PlumbsGP3ThroughputAndIOPS(t *testing.T) {
+	nodeClass := &v1.EC2NodeClass{
+		Spec: v1.EC2NodeClassSpec{
+			BlockDeviceMappings: []*v1.BlockDeviceMapping{
+				{
+					DeviceName: lo.ToPtr("/dev/xvda"),
+					EBS: &v1.BlockDevice{
+						VolumeType: lo.ToPtr("gp3"),
+						IOPS:       lo.ToPtr(int64(4000)),
+						Throughput: lo.ToPtr(int64(500)),
+					},
+				},
+			},
+		},
+	}
+	input := amifamily.RunInstancesInput(nodeClass, "ami-0123456789abcdef0", "m5.large")
+	if lo.FromPtr(input.ImageId) != "ami-0123456789abcdef0" {
+		t.Errorf("ImageId = %q, want %q", lo.FromPtr(input.ImageId), "ami-0123456789abcdef0")
+	}
+	if len(input.BlockDeviceMappings) != 1 {
+		t.Fatalf("len(BlockDeviceMappings) = %d, want 1", len(input.BlockDeviceMappings))
+	}
+	ebs := input.BlockDeviceMappings[0].Ebs
+	if ebs == nil {
+		t.Fatal("Ebs = nil, want non-nil")
+	}
+	if lo.FromPtr(ebs.Iops) != 4000 {
+		t.Errorf("Iops = %d, want 4000", lo.FromPtr(ebs.Iops))
+	}
+	if lo.FromPtr(ebs.Throughput) != 500 {
+		t.Errorf("Throughput = %d, want 500", lo.FromPtr(ebs.Throughput))
+	}
+}
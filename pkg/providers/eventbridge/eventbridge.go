@@ -0,0 +1,150 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventbridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/samber/lo"
+)
+
+// ruleName is the name given to the single rule this provider manages on its bus. A
+// provider only ever manages one bus and one rule, so there's no need to derive a
+// per-cluster name beyond the bus name itself.
+const ruleName = "karpenter-interruption"
+
+// eventPattern matches the same set of EC2 events the SQS-based interruption queue
+// is provisioned to receive: spot interruption warnings, rebalance recommendations,
+// scheduled instance change notifications, and instance state-changes.
+const eventPattern = `{
+	"source": ["aws.ec2", "aws.health"],
+	"detail-type": [
+		"EC2 Spot Instance Interruption Warning",
+		"EC2 Instance Rebalance Recommendation",
+		"EC2 Instance State-change Notification",
+		"AWS Health Event"
+	]
+}`
+
+// Provider manages an EventBridge event bus and the rule that forwards EC2
+// interruption-related events out of it to a target (typically the SQS queue the
+// interruption controller already polls). It exists as an alternative to requiring a
+// pre-created, account-wide SQS queue: callers that don't have one can provision a
+// bus per cluster instead.
+type Provider interface {
+	BusName() string
+	BusARN() string
+	EnsureRule(ctx context.Context, targetARN string) error
+	Cleanup(ctx context.Context) error
+}
+
+// API is the subset of the EventBridge client this provider calls. It exists so tests
+// can exercise DefaultProvider's create/adopt/cleanup branches against a fake instead
+// of a live account.
+type API interface {
+	CreateEventBus(ctx context.Context, in *eventbridge.CreateEventBusInput, opts ...func(*eventbridge.Options)) (*eventbridge.CreateEventBusOutput, error)
+	DescribeEventBus(ctx context.Context, in *eventbridge.DescribeEventBusInput, opts ...func(*eventbridge.Options)) (*eventbridge.DescribeEventBusOutput, error)
+	PutRule(ctx context.Context, in *eventbridge.PutRuleInput, opts ...func(*eventbridge.Options)) (*eventbridge.PutRuleOutput, error)
+	PutTargets(ctx context.Context, in *eventbridge.PutTargetsInput, opts ...func(*eventbridge.Options)) (*eventbridge.PutTargetsOutput, error)
+	RemoveTargets(ctx context.Context, in *eventbridge.RemoveTargetsInput, opts ...func(*eventbridge.Options)) (*eventbridge.RemoveTargetsOutput, error)
+	DeleteRule(ctx context.Context, in *eventbridge.DeleteRuleInput, opts ...func(*eventbridge.Options)) (*eventbridge.DeleteRuleOutput, error)
+	DeleteEventBus(ctx context.Context, in *eventbridge.DeleteEventBusInput, opts ...func(*eventbridge.Options)) (*eventbridge.DeleteEventBusOutput, error)
+}
+
+// DefaultProvider is the default implementation of Provider, backed by the
+// EventBridge API.
+type DefaultProvider struct {
+	client API
+
+	busName string
+	busARN  string
+}
+
+// NewDefaultProvider creates (or adopts, if one with this name already exists) the
+// named EventBridge bus and returns a Provider for it.
+func NewDefaultProvider(ctx context.Context, client API, busName string) (*DefaultProvider, error) {
+	out, err := client.CreateEventBus(ctx, &eventbridge.CreateEventBusInput{
+		Name: aws.String(busName),
+	})
+	if err == nil {
+		return &DefaultProvider{client: client, busName: busName, busARN: lo.FromPtr(out.EventBusArn)}, nil
+	}
+	var exists *types.ResourceAlreadyExistsException
+	if !errors.As(err, &exists) {
+		return nil, fmt.Errorf("creating event bus %q, %w", busName, err)
+	}
+	described, err := client.DescribeEventBus(ctx, &eventbridge.DescribeEventBusInput{Name: aws.String(busName)})
+	if err != nil {
+		return nil, fmt.Errorf("describing existing event bus %q, %w", busName, err)
+	}
+	return &DefaultProvider{client: client, busName: busName, busARN: lo.FromPtr(described.Arn)}, nil
+}
+
+func (p *DefaultProvider) BusName() string { return p.busName }
+func (p *DefaultProvider) BusARN() string  { return p.busARN }
+
+// EnsureRule creates (or updates) the bus's interruption rule so that it forwards
+// matching EC2 events to targetARN.
+func (p *DefaultProvider) EnsureRule(ctx context.Context, targetARN string) error {
+	if _, err := p.client.PutRule(ctx, &eventbridge.PutRuleInput{
+		Name:         aws.String(ruleName),
+		EventBusName: aws.String(p.busName),
+		EventPattern: aws.String(eventPattern),
+		State:        types.RuleStateEnabled,
+	}); err != nil {
+		return fmt.Errorf("putting rule %q on bus %q, %w", ruleName, p.busName, err)
+	}
+	if _, err := p.client.PutTargets(ctx, &eventbridge.PutTargetsInput{
+		Rule:         aws.String(ruleName),
+		EventBusName: aws.String(p.busName),
+		Targets: []types.Target{
+			{Id: aws.String("interruption-queue"), Arn: aws.String(targetARN)},
+		},
+	}); err != nil {
+		return fmt.Errorf("putting targets for rule %q, %w", ruleName, err)
+	}
+	return nil
+}
+
+// Cleanup tears down the rule and the bus this provider manages, including a bus that
+// NewDefaultProvider adopted rather than created. Targets and the rule are
+// removed before the bus itself so a partial failure can't orphan the bus in a state
+// EventBridge refuses to delete.
+func (p *DefaultProvider) Cleanup(ctx context.Context) error {
+	if _, err := p.client.RemoveTargets(ctx, &eventbridge.RemoveTargetsInput{
+		Rule:         aws.String(ruleName),
+		EventBusName: aws.String(p.busName),
+		Ids:          []string{"interruption-queue"},
+	}); err != nil {
+		return fmt.Errorf("removing targets for rule %q, %w", ruleName, err)
+	}
+	if _, err := p.client.DeleteRule(ctx, &eventbridge.DeleteRuleInput{
+		Name:         aws.String(ruleName),
+		EventBusName: aws.String(p.busName),
+	}); err != nil {
+		return fmt.Errorf("deleting rule %q, %w", ruleName, err)
+	}
+	if _, err := p.client.DeleteEventBus(ctx, &eventbridge.DeleteEventBusInput{
+		Name: aws.String(p.busName),
+	}); err != nil {
+		return fmt.Errorf("deleting event bus %q, %w", p.busName, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventbridge_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+
+	providereventbridge "github.com/aws/karpenter-provider-aws/pkg/providers/eventbridge"
+)
+
+// fakeAPI is a minimal stand-in for providereventbridge.API that lets tests control
+// what CreateEventBus/DescribeEventBus return without touching a live account.
+type fakeAPI struct {
+	providereventbridge.API
+
+	createEventBusErr error
+	busARN            string
+
+	removeTargetsCalled  bool
+	deleteRuleCalled     bool
+	deleteEventBusCalled bool
+}
+
+func (f *fakeAPI) CreateEventBus(_ context.Context, in *eventbridge.CreateEventBusInput, _ ...func(*eventbridge.Options)) (*eventbridge.CreateEventBusOutput, error) {
+	if f.createEventBusErr != nil {
+		return nil, f.createEventBusErr
+	}
+	return &eventbridge.CreateEventBusOutput{EventBusArn: aws.String(f.busARN)}, nil
+}
+
+func (f *fakeAPI) DescribeEventBus(_ context.Context, in *eventbridge.DescribeEventBusInput, _ ...func(*eventbridge.Options)) (*eventbridge.DescribeEventBusOutput, error) {
+	return &eventbridge.DescribeEventBusOutput{Arn: aws.String(f.busARN)}, nil
+}
+
+func (f *fakeAPI) PutRule(_ context.Context, in *eventbridge.PutRuleInput, _ ...func(*eventbridge.Options)) (*eventbridge.PutRuleOutput, error) {
+	return &eventbridge.PutRuleOutput{}, nil
+}
+
+func (f *fakeAPI) PutTargets(_ context.Context, in *eventbridge.PutTargetsInput, _ ...func(*eventbridge.Options)) (*eventbridge.PutTargetsOutput, error) {
+	return &eventbridge.PutTargetsOutput{}, nil
+}
+
+func (f *fakeAPI) RemoveTargets(_ context.Context, in *eventbridge.RemoveTargetsInput, _ ...func(*eventbridge.Options)) (*eventbridge.RemoveTargetsOutput, error) {
+	f.removeTargetsCalled = true
+	return &eventbridge.RemoveTargetsOutput{}, nil
+}
+
+func (f *fakeAPI) DeleteRule(_ context.Context, in *eventbridge.DeleteRuleInput, _ ...func(*eventbridge.Options)) (*eventbridge.DeleteRuleOutput, error) {
+	f.deleteRuleCalled = true
+	return &eventbridge.DeleteRuleOutput{}, nil
+}
+
+func (f *fakeAPI) DeleteEventBus(_ context.Context, in *eventbridge.DeleteEventBusInput, _ ...func(*eventbridge.Options)) (*eventbridge.DeleteEventBusOutput, error) {
+	f.deleteEventBusCalled = true
+	return &eventbridge.DeleteEventBusOutput{}, nil
+}
+
+func TestNewDefaultProvider_Creates(t *testing.T) {
+	api := &fakeAPI{busARN: "arn:aws:events:us-west-2:000000000000:event-bus/my-bus"}
+	provider, err := providereventbridge.NewDefaultProvider(context.Background(), api, "my-bus")
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if provider.BusName() != "my-bus" {
+		t.Errorf("BusName() = %q, want %q", provider.BusName(), "my-bus")
+	}
+	if provider.BusARN() != api.busARN {
+		t.Errorf("BusARN() = %q, want %q", provider.BusARN(), api.busARN)
+	}
+}
+
+func TestNewDefaultProvider_AdoptsExisting(t *testing.T) {
+	api := &fakeAPI{
+		busARN:            "arn:aws:events:us-west-2:000000000000:event-bus/my-bus",
+		createEventBusErr: &types.ResourceAlreadyExistsException{Message: aws.String("already exists")},
+	}
+	provider, err := providereventbridge.NewDefaultProvider(context.Background(), api, "my-bus")
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if provider.BusARN() != api.busARN {
+		t.Errorf("BusARN() = %q, want %q", provider.BusARN(), api.busARN)
+	}
+}
+
+func TestNewDefaultProvider_PropagatesOtherErrors(t *testing.T) {
+	api := &fakeAPI{createEventBusErr: errors.New("access denied")}
+	if _, err := providereventbridge.NewDefaultProvider(context.Background(), api, "my-bus"); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+func TestDefaultProvider_Cleanup(t *testing.T) {
+	api := &fakeAPI{busARN: "arn:aws:events:us-west-2:000000000000:event-bus/my-bus"}
+	provider, err := providereventbridge.NewDefaultProvider(context.Background(), api, "my-bus")
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if err := provider.Cleanup(context.Background()); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if !api.removeTargetsCalled || !api.deleteRuleCalled || !api.deleteEventBusCalled {
+		t.Fatalf("Cleanup() did not remove targets/rule/bus: %+v", api)
+	}
+}
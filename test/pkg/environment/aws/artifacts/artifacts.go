@@ -0,0 +1,206 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package artifacts lets suites upload debugging artifacts - controller logs, kubelet
+// logs, EC2 console output, rendered NodeClass/NodePool YAMLs, per-test JUnit - to a
+// per-run S3 prefix, so a flaky e2e run can be debugged from its uploaded artifacts
+// instead of requiring a live cluster and bastion access.
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/samber/lo"
+)
+
+// ssmCommandPollInterval is how often KubeletLogs polls SSM for the RunCommand
+// invocation to finish.
+const ssmCommandPollInterval = 2 * time.Second
+
+// presignExpiry is how long a presigned artifact URL stays valid. It only needs to
+// outlive a CI job's log retention window, not the artifact itself.
+const presignExpiry = 7 * 24 * time.Hour
+
+// ArtifactStore uploads per-spec debugging artifacts into
+// s3://bucket/clusterName/runID/suite/spec/name. It's a no-op when constructed without
+// a bucket, the same pattern Environment already uses for TimeStreamAPI, so suites
+// don't need to branch on whether ARTIFACT_BUCKET is set.
+type ArtifactStore struct {
+	client      *s3.Client
+	ec2API      *ec2.EC2
+	ssmAPI      *ssm.SSM
+	bucket      string
+	clusterName string
+	runID       string
+}
+
+// NewArtifactStore returns an ArtifactStore that uploads into bucket. If bucket is
+// empty, every upload is a no-op and Put returns an empty URL. ec2API and ssmAPI are
+// used by CollectInstanceFailureArtifacts to fetch console output and kubelet logs.
+func NewArtifactStore(client *s3.Client, ec2API *ec2.EC2, ssmAPI *ssm.SSM, bucket, clusterName, runID string) *ArtifactStore {
+	return &ArtifactStore{client: client, ec2API: ec2API, ssmAPI: ssmAPI, bucket: bucket, clusterName: clusterName, runID: runID}
+}
+
+// Put uploads body under the given suite/spec/name and returns a presigned URL for it
+// (or "" when the store has no bucket configured).
+func (a *ArtifactStore) Put(ctx context.Context, suite, spec, name string, body io.Reader) (string, error) {
+	if a.bucket == "" {
+		return "", nil
+	}
+	key := a.key(suite, spec, name)
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("reading artifact %q, %w", key, err)
+	}
+	if _, err := a.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	}); err != nil {
+		return "", fmt.Errorf("uploading artifact %q, %w", key, err)
+	}
+	url, err := a.presign(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("presigning artifact %q, %w", key, err)
+	}
+	return url, nil
+}
+
+func (a *ArtifactStore) key(suite, spec, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", a.clusterName, a.runID, suite, spec, name)
+}
+
+func (a *ArtifactStore) presign(ctx context.Context, key string) (string, error) {
+	out, err := s3.NewPresignClient(a.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(presignExpiry))
+	if err != nil {
+		return "", err
+	}
+	return out.URL, nil
+}
+
+// ConsoleOutput fetches instanceID's EC2 console output (the boot/console log the
+// hypervisor captures), decoded from the API's base64 encoding. Pass the result to
+// CollectOnFailure so a node that fails before the kubelet ever comes up still leaves
+// a debuggable artifact.
+func ConsoleOutput(ctx context.Context, ec2API *ec2.EC2, instanceID string) (io.Reader, error) {
+	out, err := ec2API.GetConsoleOutputWithContext(ctx, &ec2.GetConsoleOutputInput{
+		InstanceId: aws.String(instanceID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting console output for %q, %w", instanceID, err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(lo.FromPtr(out.Output))
+	if err != nil {
+		return nil, fmt.Errorf("decoding console output for %q, %w", instanceID, err)
+	}
+	return bytes.NewReader(decoded), nil
+}
+
+// KubeletLogs runs `journalctl -u kubelet` on instanceID via SSM RunCommand and
+// returns its stdout. Pass the result to CollectOnFailure for a node that registered
+// with SSM but never joined the cluster. Returns an error if ctx is canceled before
+// the command finishes.
+func KubeletLogs(ctx context.Context, ssmAPI *ssm.SSM, instanceID string) (io.Reader, error) {
+	send, err := ssmAPI.SendCommandWithContext(ctx, &ssm.SendCommandInput{
+		InstanceIds:  []*string{aws.String(instanceID)},
+		DocumentName: aws.String("AWS-RunShellScript"),
+		Parameters: map[string][]*string{
+			"commands": {aws.String("journalctl -u kubelet --no-pager")},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sending kubelet log command to %q, %w", instanceID, err)
+	}
+	commandID := lo.FromPtr(send.Command.CommandId)
+	for {
+		invocation, err := ssmAPI.GetCommandInvocationWithContext(ctx, &ssm.GetCommandInvocationInput{
+			CommandId:  aws.String(commandID),
+			InstanceId: aws.String(instanceID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting kubelet log command invocation for %q, %w", instanceID, err)
+		}
+		switch lo.FromPtr(invocation.Status) {
+		case ssm.CommandInvocationStatusSuccess, ssm.CommandInvocationStatusFailed:
+			return bytes.NewReader([]byte(lo.FromPtr(invocation.StandardOutputContent))), nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for kubelet log command on %q, %w", instanceID, ctx.Err())
+		case <-time.After(ssmCommandPollInterval):
+		}
+	}
+}
+
+// CollectOnFailure uploads every named artifact in logs for the given spec report,
+// logging a presigned URL for each so CI output links directly to the artifact. It's
+// meant to be called from a suite's AfterEach:
+//
+//	AfterEach(func(ctx SpecContext) {
+//		if CurrentSpecReport().Failed() {
+//			env.Artifacts.CollectOnFailure(ctx, "nodeclaim", CurrentSpecReport(), map[string]io.Reader{
+//				"controller.log": controllerLogs,
+//			})
+//		}
+//	})
+func (a *ArtifactStore) CollectOnFailure(ctx context.Context, suite string, report SpecReport, logs map[string]io.Reader) {
+	for name, body := range logs {
+		url, err := a.Put(ctx, suite, report.LeafNodeText, name, body)
+		if err != nil {
+			GinkgoWriter.Printf("failed to upload artifact %q for %q: %s\n", name, report.LeafNodeText, err)
+			continue
+		}
+		if url != "" {
+			GinkgoWriter.Printf("uploaded artifact %q for %q: %s\n", name, report.LeafNodeText, url)
+		}
+	}
+}
+
+// CollectInstanceFailureArtifacts fetches instanceID's EC2 console output and SSM
+// kubelet logs, adds them to logs under "console-output.log" and "kubelet.log", and
+// uploads the result via CollectOnFailure. A node that never registered with SSM, for
+// example, just won't have a kubelet.log - the fetch failure is logged, not fatal.
+// Nothing is fetched when the store has no bucket configured.
+func (a *ArtifactStore) CollectInstanceFailureArtifacts(ctx context.Context, suite string, report SpecReport, instanceID string, logs map[string]io.Reader) {
+	if a.bucket == "" {
+		return
+	}
+	if logs == nil {
+		logs = map[string]io.Reader{}
+	}
+	if console, err := ConsoleOutput(ctx, a.ec2API, instanceID); err != nil {
+		GinkgoWriter.Printf("failed to fetch console output for %q: %s\n", instanceID, err)
+	} else {
+		logs["console-output.log"] = console
+	}
+	if kubeletLogs, err := KubeletLogs(ctx, a.ssmAPI, instanceID); err != nil {
+		GinkgoWriter.Printf("failed to fetch kubelet logs for %q: %s\n", instanceID, err)
+	} else {
+		logs["kubelet.log"] = kubeletLogs
+	}
+	a.CollectOnFailure(ctx, suite, report, logs)
+}
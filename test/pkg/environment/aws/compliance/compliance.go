@@ -0,0 +1,355 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compliance runs a set of AWS CIS-style checks against the EC2 instances,
+// launch templates, security groups, IAM roles/instance profiles, and EBS volumes a
+// suite's Karpenter instance provisioned, so a misconfiguration (public IMDSv1,
+// unencrypted volumes, wide-open security groups, ...) shows up as a test artifact
+// instead of being discovered in a real account later.
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/samber/lo"
+)
+
+// Severity is how serious a Finding is. It does not by itself fail a suite; that's
+// controlled by REQUIRE_COMPLIANCE, see Report.FailIfRequired.
+type Severity string
+
+const (
+	SeverityHigh   Severity = "high"
+	SeverityMedium Severity = "medium"
+	SeverityLow    Severity = "low"
+)
+
+// Finding is a single check failure against a single resource.
+type Finding struct {
+	Check        string   `json:"check"`
+	Severity     Severity `json:"severity"`
+	ResourceType string   `json:"resourceType"`
+	ResourceID   string   `json:"resourceId"`
+	Message      string   `json:"message"`
+}
+
+// Report is the result of a single Scanner.ScanCreatedResources call.
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Findings    []Finding `json:"findings"`
+}
+
+// WriteJSON writes the report to path as JSON.
+func (r *Report) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling compliance report, %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing compliance report to %q, %w", path, err)
+	}
+	return nil
+}
+
+// WriteJUnit writes the report as a JUnit test suite, one testcase per check per
+// resource, so CI can surface compliance failures the same way it surfaces spec
+// failures.
+func (r *Report) WriteJUnit(path string) error {
+	var body string
+	for _, finding := range r.Findings {
+		body += fmt.Sprintf(
+			"  <testcase classname=%q name=%q><failure message=%q>%s</failure></testcase>\n",
+			finding.ResourceType, finding.Check, finding.Severity, finding.Message,
+		)
+	}
+	doc := fmt.Sprintf("<testsuite name=\"compliance\" tests=%q failures=%q>\n%s</testsuite>\n",
+		fmt.Sprint(len(r.Findings)), fmt.Sprint(len(r.Findings)), body)
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		return fmt.Errorf("writing compliance junit report to %q, %w", path, err)
+	}
+	return nil
+}
+
+// FailIfRequired returns an error summarizing the report's findings when the
+// REQUIRE_COMPLIANCE environment variable is set, and nil otherwise. Suites call this
+// from an AfterEach when they want compliance drift to fail the run rather than just
+// be recorded.
+func (r *Report) FailIfRequired() error {
+	if len(r.Findings) == 0 {
+		return nil
+	}
+	if _, ok := os.LookupEnv("REQUIRE_COMPLIANCE"); !ok {
+		return nil
+	}
+	return fmt.Errorf("%d compliance finding(s), see the compliance report for details", len(r.Findings))
+}
+
+// check inspects the resources discoverable via discoveryTags and returns any
+// findings. Checks are independent of each other so one failing to reach the API
+// doesn't prevent the rest from running.
+type check func(ctx context.Context, s *Scanner, discoveryTags map[string]string) ([]Finding, error)
+
+// EC2API is the subset of the EC2 client the checks in this package call. It exists
+// so tests can exercise Scanner against a fake instead of a live account.
+type EC2API interface {
+	DescribeInstances(*ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
+	DescribeVolumes(*ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error)
+	DescribeSecurityGroups(*ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error)
+	DescribeLaunchTemplates(*ec2.DescribeLaunchTemplatesInput) (*ec2.DescribeLaunchTemplatesOutput, error)
+	DescribeLaunchTemplateVersions(*ec2.DescribeLaunchTemplateVersionsInput) (*ec2.DescribeLaunchTemplateVersionsOutput, error)
+}
+
+// IAMAPI is the subset of the IAM client checkInstanceProfileLeastPrivilege calls.
+type IAMAPI interface {
+	ListAttachedRolePolicies(*iam.ListAttachedRolePoliciesInput) (*iam.ListAttachedRolePoliciesOutput, error)
+}
+
+// Scanner runs the compliance checks. It's wired onto Environment alongside the
+// other API clients so any suite can call env.Compliance.ScanCreatedResources.
+type Scanner struct {
+	ec2API EC2API
+	iamAPI IAMAPI
+	checks []check
+}
+
+// NewScanner returns a Scanner running the default set of CIS-style checks.
+func NewScanner(ec2API EC2API, iamAPI IAMAPI) *Scanner {
+	return &Scanner{
+		ec2API: ec2API,
+		iamAPI: iamAPI,
+		checks: []check{
+			checkIMDSv2Required,
+			checkEBSEncrypted,
+			checkNoOpenIngress,
+			checkNoPublicIPOnPrivateSubnet,
+			checkInstanceProfileLeastPrivilege,
+			checkLaunchTemplateIMDSv2Required,
+		},
+	}
+}
+
+// ScanCreatedResources runs every registered check against resources tagged with
+// discoveryTags (typically the suite's karpenter.sh/discovery tag) and returns a
+// Report. It does not fail the suite itself; callers decide via Report.FailIfRequired.
+// A check that returns an error does not stop the others: the Report holds the
+// findings of the checks that succeeded, and the errors are returned joined.
+func (s *Scanner) ScanCreatedResources(ctx context.Context, discoveryTags map[string]string) (*Report, error) {
+	report := &Report{GeneratedAt: time.Now()}
+	var errs []error
+	for _, c := range s.checks {
+		findings, err := c(ctx, s, discoveryTags)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		report.Findings = append(report.Findings, findings...)
+	}
+	return report, errors.Join(errs...)
+}
+
+func (s *Scanner) describeInstances(ctx context.Context, discoveryTags map[string]string) ([]*ec2.Instance, error) {
+	out, err := s.ec2API.DescribeInstances(&ec2.DescribeInstancesInput{
+		Filters: filtersFromTags(discoveryTags),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing instances, %w", err)
+	}
+	var instances []*ec2.Instance
+	for _, reservation := range out.Reservations {
+		instances = append(instances, reservation.Instances...)
+	}
+	return instances, nil
+}
+
+func filtersFromTags(tags map[string]string) []*ec2.Filter {
+	return lo.MapToSlice(tags, func(key, value string) *ec2.Filter {
+		return &ec2.Filter{
+			Name:   aws.String(fmt.Sprintf("tag:%s", key)),
+			Values: []*string{aws.String(value)},
+		}
+	})
+}
+
+// checkIMDSv2Required flags any instance whose metadata options don't require IMDSv2
+// (HttpTokens must be "required").
+func checkIMDSv2Required(ctx context.Context, s *Scanner, tags map[string]string) ([]Finding, error) {
+	instances, err := s.describeInstances(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, instance := range instances {
+		if instance.MetadataOptions == nil || lo.FromPtr(instance.MetadataOptions.HttpTokens) != "required" {
+			findings = append(findings, Finding{
+				Check:        "imdsv2-required",
+				Severity:     SeverityHigh,
+				ResourceType: "ec2:instance",
+				ResourceID:   lo.FromPtr(instance.InstanceId),
+				Message:      "instance does not require IMDSv2 (HttpTokens != required)",
+			})
+		}
+	}
+	return findings, nil
+}
+
+// checkEBSEncrypted flags any EBS volume attached to a Karpenter-provisioned instance
+// that isn't encrypted.
+func checkEBSEncrypted(ctx context.Context, s *Scanner, tags map[string]string) ([]Finding, error) {
+	out, err := s.ec2API.DescribeVolumes(&ec2.DescribeVolumesInput{
+		Filters: filtersFromTags(tags),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing volumes, %w", err)
+	}
+	var findings []Finding
+	for _, volume := range out.Volumes {
+		if !lo.FromPtr(volume.Encrypted) {
+			findings = append(findings, Finding{
+				Check:        "ebs-encrypted",
+				Severity:     SeverityHigh,
+				ResourceType: "ec2:volume",
+				ResourceID:   lo.FromPtr(volume.VolumeId),
+				Message:      "EBS volume is not encrypted",
+			})
+		}
+	}
+	return findings, nil
+}
+
+// checkNoOpenIngress flags any Karpenter-managed security group with an ingress rule
+// open to 0.0.0.0/0.
+func checkNoOpenIngress(ctx context.Context, s *Scanner, tags map[string]string) ([]Finding, error) {
+	out, err := s.ec2API.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		Filters: filtersFromTags(tags),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing security groups, %w", err)
+	}
+	var findings []Finding
+	for _, sg := range out.SecurityGroups {
+		for _, permission := range sg.IpPermissions {
+			for _, ipRange := range permission.IpRanges {
+				if lo.FromPtr(ipRange.CidrIp) == "0.0.0.0/0" {
+					findings = append(findings, Finding{
+						Check:        "no-open-ingress",
+						Severity:     SeverityHigh,
+						ResourceType: "ec2:security-group",
+						ResourceID:   lo.FromPtr(sg.GroupId),
+						Message:      "security group allows ingress from 0.0.0.0/0",
+					})
+				}
+			}
+		}
+	}
+	return findings, nil
+}
+
+// checkNoPublicIPOnPrivateSubnet flags any instance with a public IP that's tagged as
+// belonging to a private-cluster run.
+func checkNoPublicIPOnPrivateSubnet(ctx context.Context, s *Scanner, tags map[string]string) ([]Finding, error) {
+	if tags["karpenter.sh/private-cluster"] != "true" {
+		return nil, nil
+	}
+	instances, err := s.describeInstances(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, instance := range instances {
+		if instance.PublicIpAddress != nil {
+			findings = append(findings, Finding{
+				Check:        "no-public-ip-private-subnet",
+				Severity:     SeverityHigh,
+				ResourceType: "ec2:instance",
+				ResourceID:   lo.FromPtr(instance.InstanceId),
+				Message:      "instance in a private-cluster run has a public IP",
+			})
+		}
+	}
+	return findings, nil
+}
+
+// checkLaunchTemplateIMDSv2Required flags any Karpenter-managed launch template whose
+// $Latest version doesn't require IMDSv2. Karpenter launches nodes straight from a
+// launch template, so a misconfiguration here would otherwise only surface once an
+// instance using it comes up, and checkIMDSv2Required wouldn't catch it if the
+// template is never actually launched during the suite run.
+func checkLaunchTemplateIMDSv2Required(ctx context.Context, s *Scanner, tags map[string]string) ([]Finding, error) {
+	out, err := s.ec2API.DescribeLaunchTemplates(&ec2.DescribeLaunchTemplatesInput{
+		Filters: filtersFromTags(tags),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing launch templates, %w", err)
+	}
+	var findings []Finding
+	for _, lt := range out.LaunchTemplates {
+		versions, err := s.ec2API.DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+			LaunchTemplateId: lt.LaunchTemplateId,
+			Versions:         []*string{aws.String("$Latest")},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describing launch template versions for %q, %w", lo.FromPtr(lt.LaunchTemplateId), err)
+		}
+		for _, version := range versions.LaunchTemplateVersions {
+			metadataOptions := version.LaunchTemplateData.MetadataOptions
+			if metadataOptions == nil || lo.FromPtr(metadataOptions.HttpTokens) != "required" {
+				findings = append(findings, Finding{
+					Check:        "launch-template-imdsv2-required",
+					Severity:     SeverityHigh,
+					ResourceType: "ec2:launch-template",
+					ResourceID:   lo.FromPtr(lt.LaunchTemplateId),
+					Message:      "launch template's $Latest version does not require IMDSv2 (HttpTokens != required)",
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// checkInstanceProfileLeastPrivilege flags the Karpenter node role named by the
+// karpenter.sh/node-role tag if it has the AdministratorAccess managed policy
+// attached. No other policy is checked.
+func checkInstanceProfileLeastPrivilege(ctx context.Context, s *Scanner, tags map[string]string) ([]Finding, error) {
+	roleName, ok := tags["karpenter.sh/node-role"]
+	if !ok {
+		return nil, nil
+	}
+	out, err := s.iamAPI.ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{
+		RoleName: aws.String(roleName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing attached policies for role %q, %w", roleName, err)
+	}
+	var findings []Finding
+	for _, policy := range out.AttachedPolicies {
+		if lo.FromPtr(policy.PolicyName) == "AdministratorAccess" {
+			findings = append(findings, Finding{
+				Check:        "instance-profile-least-privilege",
+				Severity:     SeverityHigh,
+				ResourceType: "iam:role",
+				ResourceID:   roleName,
+				Message:      "node role has AdministratorAccess attached",
+			})
+		}
+	}
+	return findings, nil
+}
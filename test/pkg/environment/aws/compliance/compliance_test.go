@@ -0,0 +1,279 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compliance_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/samber/lo"
+
+	"github.com/aws/karpenter-provider-aws/test/pkg/environment/aws/compliance"
+)
+
+// fakeEC2API is a minimal stand-in for compliance.EC2API that lets tests control what
+// the Describe* calls return without touching a live account.
+type fakeEC2API struct {
+	compliance.EC2API
+
+	instances       []*ec2.Instance
+	volumes         []*ec2.Volume
+	securityGroups  []*ec2.SecurityGroup
+	launchTemplates []*ec2.LaunchTemplate
+	ltVersions      map[string][]*ec2.LaunchTemplateVersion
+}
+
+func (f *fakeEC2API) DescribeInstances(*ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{{Instances: f.instances}},
+	}, nil
+}
+
+func (f *fakeEC2API) DescribeVolumes(*ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
+	return &ec2.DescribeVolumesOutput{Volumes: f.volumes}, nil
+}
+
+func (f *fakeEC2API) DescribeSecurityGroups(*ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: f.securityGroups}, nil
+}
+
+func (f *fakeEC2API) DescribeLaunchTemplates(*ec2.DescribeLaunchTemplatesInput) (*ec2.DescribeLaunchTemplatesOutput, error) {
+	return &ec2.DescribeLaunchTemplatesOutput{LaunchTemplates: f.launchTemplates}, nil
+}
+
+func (f *fakeEC2API) DescribeLaunchTemplateVersions(in *ec2.DescribeLaunchTemplateVersionsInput) (*ec2.DescribeLaunchTemplateVersionsOutput, error) {
+	return &ec2.DescribeLaunchTemplateVersionsOutput{
+		LaunchTemplateVersions: f.ltVersions[lo.FromPtr(in.LaunchTemplateId)],
+	}, nil
+}
+
+// fakeIAMAPI is a minimal stand-in for compliance.IAMAPI.
+type fakeIAMAPI struct {
+	compliance.IAMAPI
+
+	attachedPolicies []*iam.AttachedPolicy
+}
+
+func (f *fakeIAMAPI) ListAttachedRolePolicies(*iam.ListAttachedRolePoliciesInput) (*iam.ListAttachedRolePoliciesOutput, error) {
+	return &iam.ListAttachedRolePoliciesOutput{AttachedPolicies: f.attachedPolicies}, nil
+}
+
+func requireIMDSv2() *ec2.InstanceMetadataOptionsResponse {
+	return &ec2.InstanceMetadataOptionsResponse{HttpTokens: aws.String("required")}
+}
+
+func TestScanCreatedResources_IMDSv2Required(t *testing.T) {
+	ec2API := &fakeEC2API{
+		instances: []*ec2.Instance{
+			{InstanceId: aws.String("i-good"), MetadataOptions: requireIMDSv2()},
+			{InstanceId: aws.String("i-bad"), MetadataOptions: &ec2.InstanceMetadataOptionsResponse{HttpTokens: aws.String("optional")}},
+		},
+	}
+	scanner := compliance.NewScanner(ec2API, &fakeIAMAPI{})
+	report, err := scanner.ScanCreatedResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	findings := findingsByCheck(report, "imdsv2-required")
+	if len(findings) != 1 || findings[0].ResourceID != "i-bad" {
+		t.Fatalf("imdsv2-required findings = %+v, want exactly one for i-bad", findings)
+	}
+}
+
+func TestScanCreatedResources_EBSEncrypted(t *testing.T) {
+	ec2API := &fakeEC2API{
+		volumes: []*ec2.Volume{
+			{VolumeId: aws.String("vol-good"), Encrypted: aws.Bool(true)},
+			{VolumeId: aws.String("vol-bad"), Encrypted: aws.Bool(false)},
+		},
+	}
+	scanner := compliance.NewScanner(ec2API, &fakeIAMAPI{})
+	report, err := scanner.ScanCreatedResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	findings := findingsByCheck(report, "ebs-encrypted")
+	if len(findings) != 1 || findings[0].ResourceID != "vol-bad" {
+		t.Fatalf("ebs-encrypted findings = %+v, want exactly one for vol-bad", findings)
+	}
+}
+
+func TestScanCreatedResources_NoOpenIngress(t *testing.T) {
+	ec2API := &fakeEC2API{
+		securityGroups: []*ec2.SecurityGroup{
+			{
+				GroupId: aws.String("sg-bad"),
+				IpPermissions: []*ec2.IpPermission{
+					{IpRanges: []*ec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}}},
+				},
+			},
+			{
+				GroupId: aws.String("sg-good"),
+				IpPermissions: []*ec2.IpPermission{
+					{IpRanges: []*ec2.IpRange{{CidrIp: aws.String("10.0.0.0/16")}}},
+				},
+			},
+		},
+	}
+	scanner := compliance.NewScanner(ec2API, &fakeIAMAPI{})
+	report, err := scanner.ScanCreatedResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	findings := findingsByCheck(report, "no-open-ingress")
+	if len(findings) != 1 || findings[0].ResourceID != "sg-bad" {
+		t.Fatalf("no-open-ingress findings = %+v, want exactly one for sg-bad", findings)
+	}
+}
+
+func TestScanCreatedResources_NoPublicIPOnPrivateSubnet(t *testing.T) {
+	ec2API := &fakeEC2API{
+		instances: []*ec2.Instance{
+			{InstanceId: aws.String("i-public"), MetadataOptions: requireIMDSv2(), PublicIpAddress: aws.String("1.2.3.4")},
+			{InstanceId: aws.String("i-private"), MetadataOptions: requireIMDSv2()},
+		},
+	}
+	scanner := compliance.NewScanner(ec2API, &fakeIAMAPI{})
+
+	report, err := scanner.ScanCreatedResources(context.Background(), map[string]string{"karpenter.sh/private-cluster": "true"})
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	findings := findingsByCheck(report, "no-public-ip-private-subnet")
+	if len(findings) != 1 || findings[0].ResourceID != "i-public" {
+		t.Fatalf("no-public-ip-private-subnet findings = %+v, want exactly one for i-public", findings)
+	}
+
+	report, err = scanner.ScanCreatedResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if findings := findingsByCheck(report, "no-public-ip-private-subnet"); len(findings) != 0 {
+		t.Fatalf("no-public-ip-private-subnet findings = %+v, want none when not a private-cluster run", findings)
+	}
+}
+
+func TestScanCreatedResources_InstanceProfileLeastPrivilege(t *testing.T) {
+	iamAPI := &fakeIAMAPI{
+		attachedPolicies: []*iam.AttachedPolicy{{PolicyName: aws.String("AdministratorAccess")}},
+	}
+	scanner := compliance.NewScanner(&fakeEC2API{}, iamAPI)
+
+	report, err := scanner.ScanCreatedResources(context.Background(), map[string]string{"karpenter.sh/node-role": "my-role"})
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	findings := findingsByCheck(report, "instance-profile-least-privilege")
+	if len(findings) != 1 || findings[0].ResourceID != "my-role" {
+		t.Fatalf("instance-profile-least-privilege findings = %+v, want exactly one for my-role", findings)
+	}
+
+	report, err = scanner.ScanCreatedResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if findings := findingsByCheck(report, "instance-profile-least-privilege"); len(findings) != 0 {
+		t.Fatalf("instance-profile-least-privilege findings = %+v, want none without a node-role tag", findings)
+	}
+}
+
+func TestScanCreatedResources_LaunchTemplateIMDSv2Required(t *testing.T) {
+	ec2API := &fakeEC2API{
+		launchTemplates: []*ec2.LaunchTemplate{
+			{LaunchTemplateId: aws.String("lt-good")},
+			{LaunchTemplateId: aws.String("lt-bad")},
+		},
+		ltVersions: map[string][]*ec2.LaunchTemplateVersion{
+			"lt-good": {{LaunchTemplateData: &ec2.ResponseLaunchTemplateData{MetadataOptions: requireIMDSv2()}}},
+			"lt-bad":  {{LaunchTemplateData: &ec2.ResponseLaunchTemplateData{MetadataOptions: &ec2.InstanceMetadataOptionsResponse{HttpTokens: aws.String("optional")}}}},
+		},
+	}
+	scanner := compliance.NewScanner(ec2API, &fakeIAMAPI{})
+	report, err := scanner.ScanCreatedResources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	findings := findingsByCheck(report, "launch-template-imdsv2-required")
+	if len(findings) != 1 || findings[0].ResourceID != "lt-bad" {
+		t.Fatalf("launch-template-imdsv2-required findings = %+v, want exactly one for lt-bad", findings)
+	}
+}
+
+func findingsByCheck(report *compliance.Report, check string) []compliance.Finding {
+	var out []compliance.Finding
+	for _, f := range report.Findings {
+		if f.Check == check {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	report := &compliance.Report{
+		Findings: []compliance.Finding{{Check: "ebs-encrypted", Severity: compliance.SeverityHigh, ResourceType: "ec2:volume", ResourceID: "vol-bad", Message: "EBS volume is not encrypted"}},
+	}
+	path := t.TempDir() + "/report.json"
+	if err := report.WriteJSON(path); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if !strings.Contains(string(data), `"resourceId": "vol-bad"`) {
+		t.Errorf("WriteJSON output = %s, want it to contain the finding's resourceId", data)
+	}
+}
+
+func TestReport_WriteJUnit(t *testing.T) {
+	report := &compliance.Report{
+		Findings: []compliance.Finding{{Check: "ebs-encrypted", Severity: compliance.SeverityHigh, ResourceType: "ec2:volume", ResourceID: "vol-bad", Message: "EBS volume is not encrypted"}},
+	}
+	path := t.TempDir() + "/report.xml"
+	if err := report.WriteJUnit(path); err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if !strings.Contains(string(data), `classname="ec2:volume"`) || !strings.Contains(string(data), `name="ebs-encrypted"`) {
+		t.Errorf("WriteJUnit output = %s, want a testcase for the finding", data)
+	}
+}
+
+func TestReport_FailIfRequired(t *testing.T) {
+	report := &compliance.Report{Findings: []compliance.Finding{{Check: "ebs-encrypted"}}}
+
+	if err := report.FailIfRequired(); err != nil {
+		t.Fatalf("FailIfRequired() = %v, want nil when REQUIRE_COMPLIANCE is unset", err)
+	}
+
+	t.Setenv("REQUIRE_COMPLIANCE", "true")
+	if err := report.FailIfRequired(); err == nil {
+		t.Fatal("FailIfRequired() = nil, want an error when REQUIRE_COMPLIANCE is set and there are findings")
+	}
+
+	empty := &compliance.Report{}
+	if err := empty.FailIfRequired(); err != nil {
+		t.Fatalf("FailIfRequired() = %v, want nil when there are no findings", err)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	coretest "sigs.k8s.io/karpenter/pkg/test"
 
@@ -28,10 +29,13 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws/request"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	serviceeventbridge "github.com/aws/aws-sdk-go-v2/service/eventbridge"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/fis"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	servicesqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/aws-sdk-go-v2/service/timestreamwrite"
@@ -44,11 +48,25 @@ import (
 	karpv1 "sigs.k8s.io/karpenter/pkg/apis/v1"
 
 	v1 "github.com/aws/karpenter-provider-aws/pkg/apis/v1"
+	"github.com/aws/karpenter-provider-aws/pkg/providers/eventbridge"
 	"github.com/aws/karpenter-provider-aws/pkg/providers/sqs"
 	"github.com/aws/karpenter-provider-aws/pkg/test"
+	"github.com/aws/karpenter-provider-aws/test/pkg/environment/aws/artifacts"
+	"github.com/aws/karpenter-provider-aws/test/pkg/environment/aws/compliance"
+	"github.com/aws/karpenter-provider-aws/test/pkg/environment/aws/metrics"
 	"github.com/aws/karpenter-provider-aws/test/pkg/environment/common"
 )
 
+const (
+	// metricsCollectionInterval is how often Environment.Metrics scrapes the cluster.
+	// It's deliberately short relative to a typical suite's timeout so assertions on
+	// node-level resource pressure don't have to wait out a long polling window.
+	metricsCollectionInterval = 15 * time.Second
+
+	timestreamDatabaseName = "karpenter-e2e"
+	timestreamTableName    = "node-metrics"
+)
+
 func init() {
 	karpv1.NormalizedLabels = lo.Assign(karpv1.NormalizedLabels, map[string]string{"topology.ebs.csi.aws.com/zone": corev1.LabelTopologyZone})
 }
@@ -61,19 +79,28 @@ type Environment struct {
 	*common.Environment
 	Region string
 
-	STSAPI        *sts.STS
-	EC2API        *ec2.EC2
-	SSMAPI        *ssm.SSM
-	IAMAPI        *iam.IAM
-	FISAPI        *fis.FIS
-	EKSAPI        *eks.EKS
-	TimeStreamAPI TimestreamWriteAPI
+	STSAPI         *sts.STS
+	EC2API         *ec2.EC2
+	SSMAPI         *ssm.SSM
+	IAMAPI         *iam.IAM
+	FISAPI         *fis.FIS
+	EKSAPI         *eks.EKS
+	EventBridgeAPI *serviceeventbridge.Client
+	S3API          *s3.Client
+	TimeStreamAPI  TimestreamWriteAPI
 
-	SQSProvider sqs.Provider
+	SQSProvider         sqs.Provider
+	EventBridgeProvider eventbridge.Provider
+	Metrics             *metrics.MetricsPipeline
+	Compliance          *compliance.Scanner
+	Artifacts           *artifacts.ArtifactStore
 
 	ClusterName       string
 	ClusterEndpoint   string
 	InterruptionQueue string
+	InterruptionBus   string
+	ArtifactBucket    string
+	RunID             string
 	PrivateCluster    bool
 	ZoneInfo          []ZoneInfo
 }
@@ -104,13 +131,15 @@ func NewEnvironment(t *testing.T) *Environment {
 		Region:      cfg.Region,
 		Environment: env,
 
-		STSAPI:        sts.NewFromConfig(cfg),
-		EC2API:        ec2.NewFromConfig(cfg),
-		SSMAPI:        ssm.NewFromConfig(cfg),
-		IAMAPI:        iam.NewFromConfig(cfg),
-		FISAPI:        fis.NewFromConfig(cfg),
-		EKSAPI:        eks.NewFromConfig(cfg),
-		TimeStreamAPI: GetTimeStreamAPI(cfg),
+		STSAPI:         sts.NewFromConfig(cfg),
+		EC2API:         ec2.NewFromConfig(cfg),
+		SSMAPI:         ssm.NewFromConfig(cfg),
+		IAMAPI:         iam.NewFromConfig(cfg),
+		FISAPI:         fis.NewFromConfig(cfg),
+		EKSAPI:         eks.NewFromConfig(cfg),
+		EventBridgeAPI: serviceeventbridge.NewFromConfig(cfg),
+		S3API:          s3.NewFromConfig(cfg),
+		TimeStreamAPI:  GetTimeStreamAPI(cfg),
 
 		ClusterName:     lo.Must(os.LookupEnv("CLUSTER_NAME")),
 		ClusterEndpoint: lo.Must(os.LookupEnv("CLUSTER_ENDPOINT")),
@@ -122,10 +151,51 @@ func NewEnvironment(t *testing.T) *Environment {
 		coretest.DefaultImage = fmt.Sprintf("857221689048.dkr.ecr.%s.amazonaws.com/ecr-public/eks-distro/kubernetes/pause:3.2", awsEnv.Region)
 	}
 	// Initialize the provider only if the INTERRUPTION_QUEUE environment variable is defined
+	var interruptionQueueARN string
 	if v, ok := os.LookupEnv("INTERRUPTION_QUEUE"); ok {
 		sqsapi := sqs.NewFromConfig(cfg)
 		out := lo.Must(sqsapi.GetQueueUrl(env.Context, &servicesqs.GetQueueUrlInput{QueueName: aws.String(v)}))
 		awsEnv.SQSProvider = lo.Must(sqs.NewDefaultProvider(sqsapi, lo.FromPtr(out.QueueUrl)))
+		identity := lo.Must(awsEnv.STSAPI.GetCallerIdentity(env.Context, &sts.GetCallerIdentityInput{}))
+		interruptionQueueARN = fmt.Sprintf("arn:aws:sqs:%s:%s:%s", awsEnv.Region, lo.FromPtr(identity.Account), v)
+	}
+	// Initialize the EventBridge provider only if the INTERRUPTION_BUS environment variable is defined.
+	// This is the EventBridge analogue of INTERRUPTION_QUEUE above: it gives suites a way to receive
+	// interruption events without a pre-created, account-wide SQS queue. If INTERRUPTION_QUEUE wasn't
+	// also set, we provision a scratch queue as the rule's target ourselves so this path is actually
+	// usable on its own, not just as a rule layered on top of an existing queue.
+	if v, ok := os.LookupEnv("INTERRUPTION_BUS"); ok {
+		provider := lo.Must(eventbridge.NewDefaultProvider(env.Context, awsEnv.EventBridgeAPI, v))
+		targetARN := interruptionQueueARN
+		var scratchQueueURL *string
+		if targetARN == "" {
+			sqsapi := sqs.NewFromConfig(cfg)
+			scratchQueueName := fmt.Sprintf("%s-interruption-scratch", v)
+			out := lo.Must(sqsapi.CreateQueue(env.Context, &servicesqs.CreateQueueInput{QueueName: aws.String(scratchQueueName)}))
+			attrs := lo.Must(sqsapi.GetQueueAttributes(env.Context, &servicesqs.GetQueueAttributesInput{
+				QueueUrl:       out.QueueUrl,
+				AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameQueueArn},
+			}))
+			targetARN = attrs.Attributes[string(sqstypes.QueueAttributeNameQueueArn)]
+			scratchQueueURL = out.QueueUrl
+			awsEnv.SQSProvider = lo.Must(sqs.NewDefaultProvider(sqsapi, lo.FromPtr(out.QueueUrl)))
+		}
+		lo.Must0(provider.EnsureRule(env.Context, targetARN))
+		awsEnv.EventBridgeProvider = provider
+		awsEnv.InterruptionBus = v
+		// The bus, rule, and any scratch queue we created are test-scoped; tear them down when
+		// the test that constructed this Environment finishes so suite runs don't leak them.
+		t.Cleanup(func() {
+			if err := provider.Cleanup(context.Background()); err != nil {
+				t.Logf("failed to clean up eventbridge bus %q: %s", provider.BusName(), err)
+			}
+			if scratchQueueURL != nil {
+				sqsapi := sqs.NewFromConfig(cfg)
+				if _, err := sqsapi.DeleteQueue(context.Background(), &servicesqs.DeleteQueueInput{QueueUrl: scratchQueueURL}); err != nil {
+					t.Logf("failed to clean up scratch interruption queue %q: %s", lo.FromPtr(scratchQueueURL), err)
+				}
+			}
+		})
 	}
 	// Populate ZoneInfo for all AZs in the region
 	awsEnv.ZoneInfo = lo.Map(lo.Must(awsEnv.EC2API.DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{})).AvailabilityZones, func(zone *ec2.AvailabilityZone, _ int) ZoneInfo {
@@ -135,6 +205,25 @@ func NewEnvironment(t *testing.T) *Environment {
 			ZoneType: lo.FromPtr(zone.ZoneType),
 		}
 	})
+	hostInfo := func() metrics.HostInfo {
+		return metrics.HostInfo{ClusterName: awsEnv.ClusterName, Region: awsEnv.Region, RefreshedAt: time.Now()}
+	}
+	awsEnv.Metrics = metrics.NewMetricsPipeline(metricsCollectionInterval,
+		[]metrics.Scraper{
+			metrics.NewCAdvisorScraper(awsEnv.K8sClient, hostInfo),
+			metrics.NewK8sAPIServerScraper(awsEnv.K8sClient, hostInfo),
+		},
+		newTimestreamSink(awsEnv.TimeStreamAPI),
+		metrics.NewFileSink(fmt.Sprintf("%s-metrics.jsonl", awsEnv.ClusterName)),
+	)
+	awsEnv.Compliance = compliance.NewScanner(awsEnv.EC2API, awsEnv.IAMAPI)
+	// ArtifactStore is a no-op, like TimeStreamAPI above, when ARTIFACT_BUCKET isn't set.
+	awsEnv.ArtifactBucket = os.Getenv("ARTIFACT_BUCKET")
+	awsEnv.RunID = os.Getenv("RUN_ID")
+	if awsEnv.RunID == "" {
+		awsEnv.RunID = time.Now().UTC().Format("20060102-150405")
+	}
+	awsEnv.Artifacts = artifacts.NewArtifactStore(awsEnv.S3API, awsEnv.EC2API, awsEnv.SSMAPI, awsEnv.ArtifactBucket, awsEnv.ClusterName, awsEnv.RunID)
 	return awsEnv
 }
 
@@ -156,7 +245,42 @@ func GetTimeStreamAPI(ctx context.Context, cfg aws.Config) timestreamwrite.Clien
 
 func (n *NoOpTimeStreamAPI) WriteRecords(ctx context.Context, params *timestreamwrite.WriteRecordsInput, optFns ...func(*timestreamwrite.Options)) (*timestreamwrite.WriteRecordsOutput, error) {
 	return &timestreamwrite.WriteRecordsOutput{}, nil
-} 
+}
+
+// timestreamSink adapts the existing TimestreamWriteAPI (already a no-op when metrics
+// firing is disabled for the suite) to metrics.Sink, so MetricsPipeline doesn't need
+// to know anything about Timestream directly.
+type timestreamSink struct {
+	api TimestreamWriteAPI
+}
+
+func newTimestreamSink(api TimestreamWriteAPI) *timestreamSink {
+	return &timestreamSink{api: api}
+}
+
+func (t *timestreamSink) Name() string { return "timestream" }
+
+func (t *timestreamSink) Put(ctx context.Context, samples []metrics.Sample) error {
+	records := lo.Map(samples, func(sample metrics.Sample, _ int) types.Record {
+		dimensions := lo.MapToSlice(sample.Dimensions, func(name, value string) types.Dimension {
+			return types.Dimension{Name: aws.String(name), Value: aws.String(value)}
+		})
+		return types.Record{
+			MeasureName:      aws.String(sample.Name),
+			MeasureValue:     aws.String(fmt.Sprintf("%f", sample.Value)),
+			MeasureValueType: types.MeasureValueTypeDouble,
+			Time:             aws.String(fmt.Sprintf("%d", sample.Timestamp.UnixMilli())),
+			TimeUnit:         types.TimeUnitMilliseconds,
+			Dimensions:       dimensions,
+		}
+	})
+	_, err := t.api.WriteRecords(ctx, &timestreamwrite.WriteRecordsInput{
+		DatabaseName: aws.String(timestreamDatabaseName),
+		TableName:    aws.String(timestreamTableName),
+		Records:      records,
+	})
+	return err
+}
 
 func (env *Environment) DefaultEC2NodeClass() *v1.EC2NodeClass {
 	nodeClass := test.EC2NodeClass()
@@ -182,3 +306,22 @@ func (env *Environment) DefaultEC2NodeClass() *v1.EC2NodeClass {
 	nodeClass.Spec.Role = fmt.Sprintf("KarpenterNodeRole-%s", env.ClusterName)
 	return nodeClass
 }
+
+// DefaultGP3EC2NodeClass returns a NodeClass whose root volume is a gp3 volume
+// provisioned with the given iops and throughput (MiB/s), so suites exercising the
+// gp3 throughput/IOPS knobs don't have to hand-assemble the BlockDeviceMappings.
+func (env *Environment) DefaultGP3EC2NodeClass(iops, throughput int64) *v1.EC2NodeClass {
+	nodeClass := env.DefaultEC2NodeClass()
+	nodeClass.Spec.BlockDeviceMappings = []*v1.BlockDeviceMapping{
+		{
+			DeviceName: lo.ToPtr("/dev/xvda"),
+			RootVolume: true,
+			EBS: &v1.BlockDevice{
+				VolumeType: lo.ToPtr("gp3"),
+				IOPS:       lo.ToPtr(iops),
+				Throughput: lo.ToPtr(throughput),
+			},
+		},
+	}
+	return nodeClass
+}
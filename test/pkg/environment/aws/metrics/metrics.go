@@ -0,0 +1,322 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics is an in-suite metrics subsystem modeled on the AWS Container
+// Insights receiver: scrapers poll the cluster on an interval and hand samples to a
+// pipeline that fans them out to whatever sinks the suite has configured (Timestream,
+// a local file, ...). It exists so suites can assert on node-level resource pressure
+// caused by scheduling decisions, not just on metrics Karpenter itself emits.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/samber/lo"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Sample is a single timestamped measurement produced by a Scraper. Dimensions carry
+// the identifying labels (node, pod, container, ...) a Sink needs to attribute it.
+type Sample struct {
+	Name       string
+	Value      float64
+	Timestamp  time.Time
+	Dimensions map[string]string
+}
+
+// HostInfo is the cluster/region metadata stamped onto every sample collected by this
+// package. Scrapers re-read it from their host func on every scrape, so samples stay
+// attributable across a long-running suite that provisions and terminates many nodes.
+type HostInfo struct {
+	ClusterName string
+	Region      string
+	RefreshedAt time.Time
+}
+
+// Scraper collects a batch of Samples from the cluster.
+type Scraper interface {
+	Name() string
+	Scrape(ctx context.Context) ([]Sample, error)
+}
+
+// Sink persists a batch of Samples somewhere durable.
+type Sink interface {
+	Name() string
+	Put(ctx context.Context, samples []Sample) error
+}
+
+// CAdvisorScraper reads /metrics/cadvisor from every Karpenter-provisioned node via
+// the kubelet's API server proxy, and emits per-pod/per-container CPU, memory, and
+// network counters. It's the node-level complement to K8sAPIServerScraper, which only
+// sees the state the scheduler/controllers report, not what's actually happening on
+// the host.
+type CAdvisorScraper struct {
+	kubeClient kubernetes.Interface
+	host       func() HostInfo
+
+	// metricNames restricts cAdvisor's (large) metric surface to the counters we
+	// actually use in assertions.
+	metricNames map[string]struct{}
+}
+
+func NewCAdvisorScraper(kubeClient kubernetes.Interface, host func() HostInfo) *CAdvisorScraper {
+	return &CAdvisorScraper{
+		kubeClient: kubeClient,
+		host:       host,
+		metricNames: map[string]struct{}{
+			"container_cpu_usage_seconds_total":      {},
+			"container_memory_working_set_bytes":     {},
+			"container_network_receive_bytes_total":  {},
+			"container_network_transmit_bytes_total": {},
+		},
+	}
+}
+
+func (s *CAdvisorScraper) Name() string { return "cadvisor" }
+
+func (s *CAdvisorScraper) Scrape(ctx context.Context) ([]Sample, error) {
+	nodes, err := s.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: "karpenter.sh/registered=true",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes, %w", err)
+	}
+	host := s.host()
+	var samples []Sample
+	for _, node := range nodes.Items {
+		raw, err := s.kubeClient.CoreV1().RESTClient().Get().
+			Resource("nodes").
+			Name(node.Name).
+			SubResource("proxy").
+			Suffix("metrics/cadvisor").
+			DoRaw(ctx)
+		if err != nil {
+			// A node mid-drain or not-yet-ready shouldn't fail the whole scrape.
+			continue
+		}
+		nodeSamples, err := parseCAdvisorSamples(raw, s.metricNames, node.Name, host)
+		if err != nil {
+			return nil, fmt.Errorf("parsing cadvisor metrics for node %s, %w", node.Name, err)
+		}
+		samples = append(samples, nodeSamples...)
+	}
+	return samples, nil
+}
+
+// parseCAdvisorSamples parses raw cadvisor text-format metrics, keeping only the
+// families in metricNames and stamping each resulting sample with node/host
+// dimensions. Split out of Scrape so the parsing and metric-name filtering can be unit
+// tested without a live kubelet proxy.
+func parseCAdvisorSamples(raw []byte, metricNames map[string]struct{}, node string, host HostInfo) ([]Sample, error) {
+	parsed, err := new(expfmt.TextParser).TextToMetricFamilies(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	var samples []Sample
+	for name, family := range parsed {
+		if _, ok := metricNames[name]; !ok {
+			continue
+		}
+		for _, metric := range family.Metric {
+			dimensions := map[string]string{
+				"cluster": host.ClusterName,
+				"region":  host.Region,
+				"node":    node,
+			}
+			for _, label := range metric.Label {
+				dimensions[label.GetName()] = label.GetValue()
+			}
+			samples = append(samples, Sample{
+				Name:       name,
+				Value:      metricValue(metric),
+				Timestamp:  time.Unix(metric.GetTimestampMs()/1000, 0),
+				Dimensions: dimensions,
+			})
+		}
+	}
+	return samples, nil
+}
+
+// metricValue extracts the counter/gauge value, since both container_cpu_* and
+// container_memory_* families are exported as one or the other depending on kubelet
+// version.
+func metricValue(metric *dto.Metric) float64 {
+	if c := metric.GetCounter(); c != nil {
+		return c.GetValue()
+	}
+	return metric.GetGauge().GetValue()
+}
+
+// K8sAPIServerScraper samples pod, node, and deployment state directly from the API
+// server, giving a control-plane view to pair with CAdvisorScraper's host-level view.
+type K8sAPIServerScraper struct {
+	kubeClient kubernetes.Interface
+	host       func() HostInfo
+}
+
+func NewK8sAPIServerScraper(kubeClient kubernetes.Interface, host func() HostInfo) *K8sAPIServerScraper {
+	return &K8sAPIServerScraper{kubeClient: kubeClient, host: host}
+}
+
+func (s *K8sAPIServerScraper) Name() string { return "k8s-apiserver" }
+
+func (s *K8sAPIServerScraper) Scrape(ctx context.Context) ([]Sample, error) {
+	host := s.host()
+	now := time.Now()
+	var samples []Sample
+
+	nodes, err := s.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes, %w", err)
+	}
+	samples = append(samples, Sample{Name: "cluster_node_count", Value: float64(len(nodes.Items)), Timestamp: now, Dimensions: map[string]string{"cluster": host.ClusterName}})
+
+	pods, err := s.kubeClient.CoreV1().Pods(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing pods, %w", err)
+	}
+	phaseCounts := map[corev1.PodPhase]int{}
+	for _, pod := range pods.Items {
+		phaseCounts[pod.Status.Phase]++
+	}
+	for phase, count := range phaseCounts {
+		samples = append(samples, Sample{
+			Name:       "cluster_pod_count",
+			Value:      float64(count),
+			Timestamp:  now,
+			Dimensions: map[string]string{"cluster": host.ClusterName, "phase": string(phase)},
+		})
+	}
+
+	deployments, err := s.kubeClient.AppsV1().Deployments(corev1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing deployments, %w", err)
+	}
+	for _, deployment := range deployments.Items {
+		samples = append(samples, deploymentSample(deployment, host, now))
+	}
+	return samples, nil
+}
+
+func deploymentSample(deployment appsv1.Deployment, host HostInfo, now time.Time) Sample {
+	return Sample{
+		Name:      "deployment_available_replicas",
+		Value:     float64(deployment.Status.AvailableReplicas),
+		Timestamp: now,
+		Dimensions: map[string]string{
+			"cluster":    host.ClusterName,
+			"namespace":  deployment.Namespace,
+			"deployment": deployment.Name,
+		},
+	}
+}
+
+// MetricsPipeline periodically runs a set of Scrapers and fans the resulting samples
+// out to every configured Sink. It's the type suites interact with via
+// Environment.Metrics.
+type MetricsPipeline struct {
+	scrapers []Scraper
+	sinks    []Sink
+	interval time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func NewMetricsPipeline(interval time.Duration, scrapers []Scraper, sinks ...Sink) *MetricsPipeline {
+	return &MetricsPipeline{scrapers: scrapers, sinks: lo.Compact(sinks), interval: interval}
+}
+
+// Start begins scraping on the pipeline's interval until ctx is canceled or Stop is
+// called. It's safe to call Stop without ever calling Start.
+func (p *MetricsPipeline) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	p.mu.Lock()
+	p.cancel = cancel
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.collectOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (p *MetricsPipeline) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+	}
+}
+
+func (p *MetricsPipeline) collectOnce(ctx context.Context) {
+	for _, scraper := range p.scrapers {
+		samples, err := scraper.Scrape(ctx)
+		if err != nil {
+			continue
+		}
+		for _, sink := range p.sinks {
+			_ = sink.Put(ctx, samples)
+		}
+	}
+}
+
+// FileSink appends newline-delimited JSON samples to a local file, so a suite's
+// metrics can be inspected offline without a Timestream account.
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (f *FileSink) Name() string { return "file" }
+
+func (f *FileSink) Put(_ context.Context, samples []Sample) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening metrics file %q, %w", f.path, err)
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, sample := range samples {
+		if err := encoder.Encode(sample); err != nil {
+			return fmt.Errorf("encoding sample, %w", err)
+		}
+	}
+	return nil
+}
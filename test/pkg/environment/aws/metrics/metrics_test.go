@@ -0,0 +1,166 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const cadvisorBody = `
+# HELP container_cpu_usage_seconds_total Cumulative cpu time consumed in seconds.
+# TYPE container_cpu_usage_seconds_total counter
+container_cpu_usage_seconds_total{namespace="default",pod="p1"} 12.5
+# HELP container_memory_working_set_bytes Current working set in bytes.
+# TYPE container_memory_working_set_bytes gauge
+container_memory_working_set_bytes{namespace="default",pod="p1"} 1.048576e+06
+# HELP machine_cpu_cores Number of CPU cores on the machine.
+# TYPE machine_cpu_cores gauge
+machine_cpu_cores 4
+`
+
+func TestParseCAdvisorSamples_FiltersByMetricName(t *testing.T) {
+	host := HostInfo{ClusterName: "test-cluster", Region: "us-west-2"}
+	metricNames := map[string]struct{}{
+		"container_cpu_usage_seconds_total":  {},
+		"container_memory_working_set_bytes": {},
+	}
+	samples, err := parseCAdvisorSamples([]byte(cadvisorBody), metricNames, "node-1", host)
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("len(samples) = %d, want 2 (machine_cpu_cores should be filtered out)", len(samples))
+	}
+	byName := map[string]Sample{}
+	for _, sample := range samples {
+		byName[sample.Name] = sample
+	}
+	cpu, ok := byName["container_cpu_usage_seconds_total"]
+	if !ok {
+		t.Fatal("missing container_cpu_usage_seconds_total sample")
+	}
+	if cpu.Value != 12.5 {
+		t.Errorf("cpu Value = %v, want 12.5", cpu.Value)
+	}
+	if cpu.Dimensions["cluster"] != "test-cluster" || cpu.Dimensions["region"] != "us-west-2" || cpu.Dimensions["node"] != "node-1" {
+		t.Errorf("cpu Dimensions = %+v, want cluster/region/node stamped", cpu.Dimensions)
+	}
+	if cpu.Dimensions["pod"] != "p1" || cpu.Dimensions["namespace"] != "default" {
+		t.Errorf("cpu Dimensions = %+v, want the metric's own labels carried through", cpu.Dimensions)
+	}
+
+	mem, ok := byName["container_memory_working_set_bytes"]
+	if !ok {
+		t.Fatal("missing container_memory_working_set_bytes sample")
+	}
+	if mem.Value != 1048576 {
+		t.Errorf("mem Value = %v, want 1048576", mem.Value)
+	}
+}
+
+func TestParseCAdvisorSamples_NoMatches(t *testing.T) {
+	samples, err := parseCAdvisorSamples([]byte(cadvisorBody), map[string]struct{}{"nonexistent_metric": {}}, "node-1", HostInfo{})
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	if len(samples) != 0 {
+		t.Errorf("len(samples) = %d, want 0", len(samples))
+	}
+}
+
+func TestParseCAdvisorSamples_InvalidInput(t *testing.T) {
+	if _, err := parseCAdvisorSamples([]byte("not a valid exposition format {{{"), nil, "node-1", HostInfo{}); err == nil {
+		t.Fatal("expected an error parsing malformed input, got nil")
+	}
+}
+
+func TestK8sAPIServerScraper_Scrape(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	runningPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	pendingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "p2", Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "karpenter", Namespace: "kube-system"},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 2},
+	}
+	clientset := fake.NewSimpleClientset(node, runningPod, pendingPod, deployment)
+
+	host := HostInfo{ClusterName: "test-cluster"}
+	scraper := NewK8sAPIServerScraper(clientset, func() HostInfo { return host })
+	samples, err := scraper.Scrape(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+
+	var nodeCount, running, pending, deploymentReplicas *Sample
+	for i := range samples {
+		s := &samples[i]
+		switch {
+		case s.Name == "cluster_node_count":
+			nodeCount = s
+		case s.Name == "cluster_pod_count" && s.Dimensions["phase"] == string(corev1.PodRunning):
+			running = s
+		case s.Name == "cluster_pod_count" && s.Dimensions["phase"] == string(corev1.PodPending):
+			pending = s
+		case s.Name == "deployment_available_replicas":
+			deploymentReplicas = s
+		}
+	}
+
+	if nodeCount == nil || nodeCount.Value != 1 {
+		t.Errorf("cluster_node_count = %+v, want Value 1", nodeCount)
+	}
+	if running == nil || running.Value != 1 {
+		t.Errorf("cluster_pod_count{phase=Running} = %+v, want Value 1", running)
+	}
+	if pending == nil || pending.Value != 1 {
+		t.Errorf("cluster_pod_count{phase=Pending} = %+v, want Value 1", pending)
+	}
+	if deploymentReplicas == nil || deploymentReplicas.Value != 2 {
+		t.Errorf("deployment_available_replicas = %+v, want Value 2", deploymentReplicas)
+	}
+	if deploymentReplicas != nil && (deploymentReplicas.Dimensions["namespace"] != "kube-system" || deploymentReplicas.Dimensions["deployment"] != "karpenter") {
+		t.Errorf("deployment_available_replicas Dimensions = %+v, want namespace/deployment stamped", deploymentReplicas.Dimensions)
+	}
+}
+
+func TestK8sAPIServerScraper_Scrape_NoResources(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	scraper := NewK8sAPIServerScraper(clientset, func() HostInfo { return HostInfo{} })
+	samples, err := scraper.Scrape(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error, %v", err)
+	}
+	for _, sample := range samples {
+		if sample.Name == "cluster_node_count" && sample.Value != 0 {
+			t.Errorf("cluster_node_count = %v, want 0", sample.Value)
+		}
+		if strings.HasPrefix(sample.Name, "cluster_pod_count") {
+			t.Errorf("unexpected pod count sample with no pods: %+v", sample)
+		}
+	}
+}